@@ -0,0 +1,64 @@
+package logit
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// SetBacktraceAt sets the collection of file:line triggers at which a log
+// call dumps the current goroutine's stack trace alongside its usual
+// output, e.g. "server.go:42,auth.go:117". Matching is exact on the short
+// file name computed by caller() and exact on line number. The whole
+// trigger set is swapped in atomically via an atomic.Value, so the
+// lt.output hot path never takes a lock to check it.
+func (lg *Logger) SetBacktraceAt(spec string) error {
+	at := make(map[string]map[int]struct{})
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		colon := strings.LastIndex(entry, ":")
+		if colon < 0 {
+			return fmt.Errorf("logit: invalid backtrace trigger %q: missing ':'", entry)
+		}
+		file, lineStr := entry[:colon], entry[colon+1:]
+		if file == "" {
+			return fmt.Errorf("logit: invalid backtrace trigger %q: empty file", entry)
+		}
+		line, err := strconv.Atoi(lineStr)
+		if err != nil {
+			return fmt.Errorf("logit: invalid backtrace trigger %q: %v", entry, err)
+		}
+		lines := at[file]
+		if lines == nil {
+			lines = make(map[int]struct{})
+			at[file] = lines
+		}
+		lines[line] = struct{}{}
+	}
+
+	lg.lt.backtraceAt.Store(at)
+	return nil
+}
+
+// ClearBacktraceAt removes every backtrace trigger set by SetBacktraceAt.
+func (lg *Logger) ClearBacktraceAt() {
+	lg.lt.backtraceAt.Store(map[string]map[int]struct{}{})
+}
+
+// backtraceMatch reports whether file:line is a configured backtrace
+// trigger.
+func (lt *loggerT) backtraceMatch(file string, line int) bool {
+	at, ok := lt.backtraceAt.Load().(map[string]map[int]struct{})
+	if !ok || len(at) == 0 {
+		return false
+	}
+	lines, ok := at[file]
+	if !ok {
+		return false
+	}
+	_, ok = lines[line]
+	return ok
+}
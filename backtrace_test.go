@@ -0,0 +1,48 @@
+package logit
+
+import (
+	"bytes"
+	"fmt"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestSetBacktraceAtAppendsStack(t *testing.T) {
+	var buf bytes.Buffer
+	lg, err := New("", 0, Linfo, 0, WriterSink(&buf))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	defer lg.Close()
+
+	_, _, line, _ := runtime.Caller(0)
+	if err := lg.SetBacktraceAt(fmt.Sprintf("backtrace_test.go:%d", line+4)); err != nil {
+		t.Fatalf("SetBacktraceAt: %v", err)
+	}
+	lg.Infof("triggering line") // must stay 4 lines below runtime.Caller(0) above
+
+	if !strings.Contains(buf.String(), "goroutine") {
+		t.Errorf("output missing goroutine trace after matching SetBacktraceAt trigger: %q", buf.String())
+	}
+}
+
+func TestClearBacktraceAtTurnsOff(t *testing.T) {
+	var buf bytes.Buffer
+	lg, err := New("", 0, Linfo, 0, WriterSink(&buf))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	defer lg.Close()
+
+	_, _, line, _ := runtime.Caller(0)
+	if err := lg.SetBacktraceAt(fmt.Sprintf("backtrace_test.go:%d", line+5)); err != nil {
+		t.Fatalf("SetBacktraceAt: %v", err)
+	}
+	lg.ClearBacktraceAt()
+	lg.Infof("no trigger") // registered trigger for this exact line, but cleared before logging
+
+	if strings.Contains(buf.String(), "goroutine") {
+		t.Errorf("output still has a goroutine trace after ClearBacktraceAt: %q", buf.String())
+	}
+}
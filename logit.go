@@ -1,10 +1,10 @@
 package logit
 
 import (
-	"bufio"
 	"bytes"
 	"fmt"
 	"os"
+	"os/user"
 	"path/filepath"
 	"runtime"
 	"strings"
@@ -14,30 +14,32 @@ import (
 )
 
 type loggerT struct {
-	mu         sync.Mutex
-	freeList   *buffer
-	freeListMu sync.Mutex
-	out        *bufio.Writer
-	file       *os.File
-	stderr     bool
-	flag       uint32
+	mu          sync.Mutex
+	freeList    *buffer
+	freeListMu  sync.Mutex
+	sinks       []Sink
+	flag        uint32
+	backtraceAt atomic.Value // map[string]map[int]struct{}, set via SetBacktraceAt
 }
 
 const bufferSize = 256 * 1024
 
-var l *loggerT
-
 type Logger struct {
-	level       Level
-	crit        verbose
-	alert       verbose
-	err         verbose
-	warning     verbose
-	notice      verbose
-	info        verbose
-	debug       verbose
-	infoPrefix  string
-	debugPrefix string
+	level          Level
+	lt             *loggerT
+	vmodule        atomic.Value  // *vmoduleState, set via SetVModule
+	fields         []interface{} // key/value pairs bound by With, prepended to every *w call
+	errLimiterOnce sync.Once
+	errLimiter     *tokenBucket // lazily created by LimitErr
+	crit           verbose
+	alert          verbose
+	err            verbose
+	warning        verbose
+	notice         verbose
+	info           verbose
+	debug          verbose
+	infoPrefix     string
+	debugPrefix    string
 }
 
 var (
@@ -47,6 +49,15 @@ var (
 	userName = "unknownuser"
 )
 
+func init() {
+	if h, err := os.Hostname(); err == nil && h != "" {
+		host = h
+	}
+	if u, err := user.Current(); err == nil && u.Username != "" {
+		userName = u.Username
+	}
+}
+
 type Level uint32
 
 const (
@@ -117,15 +128,25 @@ func (l *Level) get() Level {
 	return Level(atomic.LoadUint32((*uint32)(l)))
 }
 
-type verbose bool
+// verbose is the gate returned by Logger.v/VDepth and embedded in every
+// Logger.X wrapper method: ok reports whether the call site is enabled, and
+// lt is the loggerT that should receive the output if so.
+type verbose struct {
+	ok bool
+	lt *loggerT
+}
 
-// v returns true if the Logger is configured at or above the given Level.
+// v returns a verbose gate reporting whether the Logger is configured at or
+// above the given Level. If the global level doesn't clear it, and vmodule
+// rules are configured, the caller's file is resolved and checked against
+// those rules before giving up; this keeps the common case (no vmodule
+// rules, or verbosity already high enough) free of the runtime.Caller cost.
 func (lg *Logger) v(level Level) verbose {
-	if level <= lg.level {
-		return true
+	if level <= lg.level.get() {
+		return verbose{ok: true, lt: lg.lt}
 	}
 
-	return false
+	return lg.VDepth(1, level)
 }
 
 func (lg *Logger) SetVerbosity(level Level) {
@@ -140,6 +161,8 @@ const (
 	Lstderr   uint32 = 1 << iota // Sets output to stderr.
 	Lfile                        // Sets output to file.
 	Ljournald                    // Sets output to have JournalD identifiers.
+	Llogfmt                      // Renders records as logfmt (k=v) instead of the human-readable header.
+	Ljson                        // Renders records as JSON instead of the human-readable header. Takes precedence over Llogfmt.
 )
 
 // flushDaemon periodically flushes the log file buffers at the given interval.
@@ -151,40 +174,46 @@ func (lt *loggerT) flushDaemon(flushInterval time.Duration) {
 
 // New creates and returns a new Logger. Depending on the bitstring flag that
 // is set, the logger will output log messages that are at or below the
-// specified Level to the given file location and/or to stderr. The logger may
-// be configured to output JournalD prefixes for color-coding within the
-// `journald` facility. All options can be used together.
-func New(file string, flushInterval time.Duration, level Level, flag uint32) (*Logger, error) {
-	li := Logger{}
-	li.level = level
-	if l == nil {
-		l = &loggerT{
-			flag: flag,
+// specified Level to the given file location and/or to stderr; these two are
+// appended to sinks as a FileSink and StderrSink respectively, ahead of
+// whatever sinks the caller supplies directly, so `sinks` can add syslog,
+// journald, or any other Sink on top of the flag-based defaults. The logger
+// may be configured to output JournalD prefixes for color-coding within the
+// `journald` facility via the Ljournald flag; this is independent of, and
+// predates, the native JournaldSink. Every call to New creates an
+// independent Logger with its own Sinks and Level, plus its own flush daemon
+// when Lfile is set (without Lfile, sinks are flushed only by Close) -- unlike
+// earlier versions of this package, a second call to New no longer reuses
+// the first one's state.
+func New(file string, flushInterval time.Duration, level Level, flag uint32, sinks ...Sink) (*Logger, error) {
+	lt := &loggerT{flag: flag}
+
+	if flag&Lfile == Lfile {
+		fs, err := FileSink(file)
+		if err != nil {
+			return nil, err
 		}
+		lt.sinks = append(lt.sinks, fs)
+	}
 
-		if flag&Lfile == Lfile {
-			f, err := os.OpenFile(file, os.O_APPEND|os.O_WRONLY|os.O_CREATE, 0666)
-			if err != nil {
-				return nil, err
-			}
-			l.out = bufio.NewWriterSize(f, bufferSize)
-			l.file = f
-			go l.flushDaemon(flushInterval)
-		}
+	if flag&Lstderr == Lstderr {
+		lt.sinks = append(lt.sinks, StderrSink())
+	}
 
-		if flag&Lstderr == Lstderr {
-			l.stderr = true
-		}
+	lt.sinks = append(lt.sinks, sinks...)
+
+	if flag&Lfile == Lfile {
+		go lt.flushDaemon(flushInterval)
 	}
 
-	return &li, nil
+	return &Logger{level: level, lt: lt}, nil
 }
 
-// Close does a delayed buffer flush and closes the log file.
+// Close does a delayed buffer flush and closes every sink.
 func (lg *Logger) Close() {
-	if l.out != nil {
-		l.timeoutFlush(time.Second * 3)
-		l.file.Close()
+	lg.lt.timeoutFlush(time.Second * 3)
+	for _, s := range lg.lt.sinks {
+		s.Close()
 	}
 }
 
@@ -243,15 +272,17 @@ func stacks(all bool) []byte {
 	return trace
 }
 
-// timeoutFlush calls Flush and returns when it completes or after timeout
-// elapses, whichever happens first.  This is needed because the hooks invoked
-// by Flush may deadlock when glog.Fatal is called from a hook that holds
-// a lock.
+// timeoutFlush calls Flush on every sink and returns when they all complete
+// or after timeout elapses, whichever happens first.  This is needed because
+// the hooks invoked by Flush may deadlock when glog.Fatal is called from a
+// hook that holds a lock.
 func (lt *loggerT) timeoutFlush(timeout time.Duration) {
 	done := make(chan bool, 1)
 	go func() {
 		lt.mu.Lock()
-		lt.out.Flush()
+		for _, s := range lt.sinks {
+			s.Flush()
+		}
 		lt.mu.Unlock()
 		done <- true
 	}()
@@ -263,35 +294,31 @@ func (lt *loggerT) timeoutFlush(timeout time.Duration) {
 	}
 }
 
-func (lt *loggerT) output(lev Level, buf *buffer, file string, line int) {
+func (lt *loggerT) output(meta Meta, buf *buffer) {
+	if lt.backtraceMatch(meta.File, meta.Line) {
+		buf.Write(stacks(false))
+	}
+
 	lt.mu.Lock()
 	data := buf.Bytes()
-	if lt.file != nil {
-		lt.out.Write(data)
-	}
-	if lt.stderr {
-		os.Stderr.Write(data)
-	}
-	if lev == Lfatal {
-		// Dump all goroutine stacks before exiting.
-		// First, make sure we see the trace for the current goroutine on standard error.
-		// If -logtostderr has been specified, the loop below will do that anyway
-		// as the first stack in the full dump.
-		if !lt.stderr {
-			os.Stderr.Write(stacks(false))
-		}
-		// Write the stack trace for all goroutines to the files.
+	for _, s := range lt.sinks {
+		s.Emit(meta, data)
+	}
+	if meta.Level == Lfatal {
+		// Dump all goroutine stacks before exiting. Always show the current
+		// goroutine's trace on stderr directly first, in case none of the
+		// configured sinks write there.
+		os.Stderr.Write(stacks(false))
 		trace := stacks(true)
-		if lt.file != nil {
-			lt.out.Write(trace)
-		}
-		if lt.stderr {
-			os.Stderr.Write(trace)
+		traceMeta := meta
+		traceMeta.Message = trace
+		for _, s := range lt.sinks {
+			s.Emit(traceMeta, trace)
 		}
 		lt.mu.Unlock()
-		if lt.file != nil {
-			lt.timeoutFlush(10 * time.Second)
-			_ = lt.file.Close()
+		lt.timeoutFlush(10 * time.Second)
+		for _, s := range lt.sinks {
+			s.Close()
 		}
 		os.Exit(255)
 	}
@@ -304,36 +331,64 @@ func (lt *loggerT) print(lev Level, args ...interface{}) {
 }
 
 func (lt *loggerT) printfDepth(lev Level, depth int, format string, args ...interface{}) {
-	buf, file, line := lt.header(lev, depth)
-	fmt.Fprintf(buf, format, args...)
-	if buf.Bytes()[buf.Len()-1] != '\n' {
-		buf.WriteByte('\n')
-	}
-	lt.output(lev, buf, file, line)
+	file, line := lt.caller(depth)
+	msg := fmt.Sprintf(format, args...)
+	lt.emit(lev, file, line, msg, nil)
 }
 
 func (lt *loggerT) printDepth(lev Level, depth int, args ...interface{}) {
-	buf, file, line := lt.header(lev, depth)
-	fmt.Fprint(buf, args...)
-	if buf.Bytes()[buf.Len()-1] != '\n' {
-		buf.WriteByte('\n')
-	}
-	lt.output(lev, buf, file, line)
+	file, line := lt.caller(depth)
+	msg := fmt.Sprint(args...)
+	lt.emit(lev, file, line, msg, nil)
 }
 
 func (lt *loggerT) println(lev Level, args ...interface{}) {
-	buf, file, line := lt.header(lev, 1)
-	fmt.Fprintln(buf, args...)
-	lt.output(lev, buf, file, line)
+	file, line := lt.caller(1)
+	msg := strings.TrimSuffix(fmt.Sprintln(args...), "\n")
+	lt.emit(lev, file, line, msg, nil)
 }
 
 func (lt *loggerT) printf(lev Level, format string, args ...interface{}) {
-	buf, file, line := lt.header(lev, 1)
-	fmt.Fprintf(buf, format, args...)
-	if buf.Bytes()[buf.Len()-1] != '\n' {
-		buf.WriteByte('\n')
+	file, line := lt.caller(1)
+	msg := fmt.Sprintf(format, args...)
+	lt.emit(lev, file, line, msg, nil)
+}
+
+// printw renders msg and its key/value pairs through the Logger's
+// configured encoder (see structured.go) and emits the result.
+func (lt *loggerT) printw(lev Level, depth int, msg string, keysAndValues ...interface{}) {
+	file, line := lt.caller(depth)
+	lt.emit(lev, file, line, msg, keysAndValues)
+}
+
+// printwAt behaves like printw but attributes the record to an already-
+// resolved file:line rather than walking the stack via caller(); callers
+// that recover their own call site (e.g. slogHandler, from the slog.Record
+// PC) use this to avoid attributing the log line to their own frame.
+func (lt *loggerT) printwAt(lev Level, file string, line int, msg string, keysAndValues ...interface{}) {
+	lt.emit(lev, file, line, msg, keysAndValues)
+}
+
+// emit renders a record via the Logger's configured encoder and hands it to
+// output. It's the single path plain print calls and the structured *w API
+// both funnel through, so the encoding flags (Llogfmt/Ljson) and the human-
+// readable default apply uniformly.
+func (lt *loggerT) emit(lev Level, file string, line int, msg string, keysAndValues []interface{}) {
+	buf := lt.render(lev, file, line, msg, keysAndValues)
+	lt.output(lt.meta(lev, file, line, msg), buf)
+}
+
+// meta builds the Meta describing a record for the configured Sinks.
+func (lt *loggerT) meta(lev Level, file string, line int, msg string) Meta {
+	return Meta{
+		Level:     lev,
+		Time:      time.Now(),
+		Pid:       pid,
+		Goroutine: goroutineID(),
+		File:      file,
+		Line:      line,
+		Message:   []byte(strings.TrimRight(msg, "\n")),
 	}
-	lt.output(lev, buf, file, line)
 }
 
 // Some custom tiny helper functions to print the log header efficiently.
@@ -376,18 +431,20 @@ func (buf *buffer) someDigits(i, d int) int {
 	return copy(buf.tmp[i:], buf.tmp[j:])
 }
 
-func (lt *loggerT) header(lev Level, depth int) (*buffer, string, int) {
+// caller resolves the short file name and line number of the call site
+// `depth` frames above the Logger method that ultimately invoked it. The
+// skip count mirrors the call chain built by the Logger.X -> verbose.X ->
+// loggerT.printXxx wrappers; see the individual print* methods for how
+// depth is threaded through.
+func (lt *loggerT) caller(depth int) (string, int) {
 	_, file, line, ok := runtime.Caller(3 + depth)
 	if !ok {
-		file = "???"
-		line = 1
-	} else {
-		slash := strings.LastIndex(file, "/")
-		if slash >= 0 {
-			file = file[slash+1:]
-		}
+		return "???", 1
+	}
+	if slash := strings.LastIndex(file, "/"); slash >= 0 {
+		file = file[slash+1:]
 	}
-	return lt.formatHeader(lev, file, line), file, line
+	return file, line
 }
 
 // formatHeader formats a log header using the provided file name and line number.
@@ -440,99 +497,99 @@ func (lt *loggerT) formatHeader(lev Level, file string, line int) *buffer {
 
 // Crit logs a message if verbosity is set appropriately.
 func (v verbose) Crit(msg string) {
-	if v {
-		l.println(Lcrit, msg)
+	if v.ok {
+		v.lt.println(Lcrit, msg)
 	}
 }
 
 // Critf logs a formatted message if verbosity is set appropriately.
 func (v verbose) Critf(fmt string, args ...interface{}) {
-	if v {
-		l.printf(Lcrit, fmt, args...)
+	if v.ok {
+		v.lt.printf(Lcrit, fmt, args...)
 	}
 }
 
 // Alert logs a message if verbosity is set appropriately.
 func (v verbose) Alert(msg string) {
-	if v {
-		l.println(Lalert, msg)
+	if v.ok {
+		v.lt.println(Lalert, msg)
 	}
 }
 
 // Alertf logs a message if verbosity is set appropriately.
 func (v verbose) Alertf(fmt string, args ...interface{}) {
-	if v {
-		l.printf(Lalert, fmt, args...)
+	if v.ok {
+		v.lt.printf(Lalert, fmt, args...)
 	}
 }
 
 // Error logs a message if verbosity is set appropriately.
 func (v verbose) Error(msg string) {
-	if v {
-		l.println(Lerr, msg)
+	if v.ok {
+		v.lt.println(Lerr, msg)
 	}
 }
 
 // Errorf logs a message if verbosity is set appropriately.
 func (v verbose) Errorf(fmt string, args ...interface{}) {
-	if v {
-		l.printf(Lerr, fmt, args...)
+	if v.ok {
+		v.lt.printf(Lerr, fmt, args...)
 	}
 }
 
 // Warn logs a message if verbosity is set appropriately.
 func (v verbose) Warn(msg string) {
-	if v {
-		l.println(Lwarning, msg)
+	if v.ok {
+		v.lt.println(Lwarning, msg)
 	}
 }
 
 // Warnf logs a message if verbosity is set appropriately.
 func (v verbose) Warnf(fmt string, args ...interface{}) {
-	if v {
-		l.printf(Lwarning, fmt, args...)
+	if v.ok {
+		v.lt.printf(Lwarning, fmt, args...)
 	}
 }
 
 // Notice logs a message if verbosity is set appropriately.
 func (v verbose) Notice(msg string) {
-	if v {
-		l.println(Lnotice, msg)
+	if v.ok {
+		v.lt.println(Lnotice, msg)
 	}
 }
 
 // Noticef logs a message if verbosity is set appropriately.
 func (v verbose) Noticef(fmt string, args ...interface{}) {
-	if v {
-		l.printf(Lnotice, fmt, args...)
+	if v.ok {
+		v.lt.printf(Lnotice, fmt, args...)
 	}
 }
 
 // Info logs a message if verbosity is set appropriately.
 func (v verbose) Info(msg string) {
-	if v {
-		l.println(Linfo, msg)
+	if v.ok {
+		v.lt.println(Linfo, msg)
 	}
 }
 
 // Infof logs a message if verbosity is set appropriately.
 func (v verbose) Infof(fmt string, args ...interface{}) {
-	if v {
-		l.printf(Linfo, fmt, args...)
+	if v.ok {
+		v.lt.printf(Linfo, fmt, args...)
 	}
 }
 
 // Debug logs a message if verbosity is set appropriately.
 func (v verbose) Debug(msg string) {
-	if v {
-		l.println(Ldebug, msg)
+	if v.ok {
+		v.lt.println(Ldebug, msg)
 	}
 }
 
 // Debugf logs a message if verbosity is set appropriately.
 func (v verbose) Debugf(fmt string, args ...interface{}) {
-	if v {
-		l.printf(Ldebug, fmt, args...)
+	if v.ok {
+		v.lt.printf(Ldebug, fmt, args...)
 	}
 }
 
@@ -562,12 +619,12 @@ func (lg *Logger) Alertf(fmt string, args ...interface{}) {
 
 // Fatal logs a message and terminates the application.
 func (lg *Logger) Fatal(msg string) {
-	l.printDepth(Lfatal, 0, msg)
+	lg.lt.printDepth(Lfatal, 0, msg)
 }
 
 // Fatalf logs a formatted message and terminates the application.
 func (lg *Logger) Fatalf(fmt string, args ...interface{}) {
-	l.printfDepth(Lfatal, 0, fmt, args...)
+	lg.lt.printfDepth(Lfatal, 0, fmt, args...)
 }
 
 // Error logs a message if verbosity is set appropriately.
@@ -0,0 +1,141 @@
+package logit
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// everyCounters tracks, per call site, how many times Every has been asked
+// to gate a log line there.
+var everyCounters sync.Map // map[string]*int64
+
+// everyTimers tracks, per call site, the UnixNano of the last line EveryT
+// let through.
+var everyTimers sync.Map // map[string]*int64
+
+// callerFileLine returns the short file name and line number of the stack
+// frame `skip` levels above callerFileLine's own caller.
+func callerFileLine(skip int) (file string, line int, ok bool) {
+	_, file, line, ok = runtime.Caller(skip + 1)
+	if !ok {
+		return "", 0, false
+	}
+	if slash := strings.LastIndex(file, "/"); slash >= 0 {
+		file = file[slash+1:]
+	}
+	return file, line, true
+}
+
+// Every gates v so that only every nth call from a given source line is
+// let through: the call site is hashed (by file:line) into a counter, and
+// the gate opens on the 1st, (n+1)th, (2n+1)th, ... hit. n <= 0 disables
+// the gate (v is returned unchanged).
+func (v verbose) Every(n int) verbose {
+	if !v.ok || n <= 0 {
+		return v
+	}
+
+	file, line, ok := callerFileLine(1)
+	if !ok {
+		return v
+	}
+	key := fmt.Sprintf("%s:%d", file, line)
+
+	counterI, _ := everyCounters.LoadOrStore(key, new(int64))
+	counter := counterI.(*int64)
+	hit := atomic.AddInt64(counter, 1) - 1
+
+	return verbose{ok: hit%int64(n) == 0, lt: v.lt}
+}
+
+// EveryT gates v so that a given source line logs at most once per d: the
+// call site is hashed (by file:line) into the UnixNano of its last
+// successful log, and the gate opens only once d has elapsed since. d <= 0
+// disables the gate (v is returned unchanged).
+func (v verbose) EveryT(d time.Duration) verbose {
+	if !v.ok || d <= 0 {
+		return v
+	}
+
+	file, line, ok := callerFileLine(1)
+	if !ok {
+		return v
+	}
+	key := fmt.Sprintf("%s:%d", file, line)
+
+	lastI, _ := everyTimers.LoadOrStore(key, new(int64))
+	last := lastI.(*int64)
+	now := time.Now().UnixNano()
+
+	for {
+		prev := atomic.LoadInt64(last)
+		if now-prev < int64(d) {
+			return verbose{ok: false, lt: v.lt}
+		}
+		if atomic.CompareAndSwapInt64(last, prev, now) {
+			return v
+		}
+	}
+}
+
+// tokenBucket is a minimal token-bucket rate limiter: tokens refill
+// continuously at rate per second, up to a burst of one second's worth.
+type tokenBucket struct {
+	mu     sync.Mutex
+	rate   float64
+	tokens float64
+	last   time.Time
+}
+
+func (b *tokenBucket) allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := time.Now()
+	b.tokens += now.Sub(b.last).Seconds() * b.rate
+	if b.tokens > b.rate {
+		b.tokens = b.rate
+	}
+	b.last = now
+
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+// LimitErr gates Error-level logging through a token bucket refilling at
+// perSec tokens per second, so a flood of distinct error call sites can't
+// overwhelm the sink. The bucket is shared by every call to LimitErr on lg,
+// and is created with the given rate the first time LimitErr is called;
+// perSec is ignored on later calls.
+func (lg *Logger) LimitErr(perSec int) verbose {
+	v := lg.v(Lerr)
+	if !v.ok {
+		return v
+	}
+
+	lg.errLimiterOnce.Do(func() {
+		lg.errLimiter = &tokenBucket{rate: float64(perSec), tokens: float64(perSec), last: time.Now()}
+	})
+	if !lg.errLimiter.allow() {
+		return verbose{ok: false, lt: v.lt}
+	}
+	return v
+}
+
+// ErrorDepthf logs a formatted error message if verbosity is set
+// appropriately, attributing it to the call site `depth` frames above
+// ErrorDepthf's caller -- depth 0 behaves exactly like Errorf, and each
+// additional layer of wrapping around this call should add 1.
+func (lg *Logger) ErrorDepthf(depth int, format string, args ...interface{}) {
+	v := lg.VDepth(depth, Lerr)
+	if v.ok {
+		v.lt.printfDepth(Lerr, depth, format, args...)
+	}
+}
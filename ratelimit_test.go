@@ -0,0 +1,23 @@
+package logit
+
+import "testing"
+
+func TestTokenBucketSeedsFullBurst(t *testing.T) {
+	b := &tokenBucket{rate: 10, tokens: 10}
+	for i := 0; i < 10; i++ {
+		if !b.allow() {
+			t.Fatalf("allow() = false on call %d, want true (burst should admit rate tokens immediately)", i)
+		}
+	}
+	if b.allow() {
+		t.Fatalf("allow() = true after burst exhausted, want false")
+	}
+}
+
+func TestLimitErrAdmitsFirstBurst(t *testing.T) {
+	lg := &Logger{level: Lerr}
+	v := lg.LimitErr(5)
+	if !v.ok {
+		t.Fatalf("LimitErr(5) gated the very first call, want it admitted (burst should seed tokens=rate)")
+	}
+}
@@ -0,0 +1,297 @@
+package logit
+
+import (
+	"bufio"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RotateConfig controls when and how a RotatingFileSink rotates its backing
+// file. The zero value disables every form of automatic rotation; Logger.
+// Rotate (or a SIGHUP handler that calls it) is then the only way backups
+// get created.
+type RotateConfig struct {
+	MaxSize     int64         // rotate once the file would exceed this many bytes; 0 disables size-based rotation
+	MaxAge      time.Duration // delete backups older than this; 0 keeps backups regardless of age
+	MaxBackups  int           // keep at most this many backups; 0 keeps them all
+	Compress    bool          // gzip backups after rotating
+	DailyRotate bool          // rotate once per day at RotateAt
+	RotateAt    time.Duration // time of day (offset from midnight) DailyRotate fires at
+}
+
+// Rotator is implemented by sinks that support rotating their backing file,
+// so Logger.Rotate can find and trigger them without knowing the concrete
+// sink type.
+type Rotator interface {
+	Rotate() error
+}
+
+// rotatingFileSink is a Sink that writes to a file, rotating it to a
+// timestamped backup per RotateConfig and reopening a fresh file at path.
+type rotatingFileSink struct {
+	mu        sync.Mutex
+	path      string
+	level     Level
+	cfg       RotateConfig
+	file      *os.File
+	out       *bufio.Writer
+	written   int64
+	stop      chan struct{}
+	closeOnce sync.Once
+	closeErr  error
+}
+
+// RotatingFileSink opens (or creates) file in append mode and returns a Sink
+// that rotates it according to cfg. level is used only to label rotated
+// backups, mirroring glog's per-severity log files (program.host.user.log.
+// LEVEL.YYYYMMDD-HHMMSS.pid); pass the lowest Level this file is expected to
+// receive.
+//
+// RotatingFileSink is opt-in: New's Lfile flag still opens its own,
+// non-rotating file, so pass a RotatingFileSink to New as a Sink option
+// instead of setting Lfile, or the two will race writes to different files.
+func RotatingFileSink(file string, level Level, cfg RotateConfig) (Sink, error) {
+	f, err := os.OpenFile(file, os.O_APPEND|os.O_WRONLY|os.O_CREATE, 0666)
+	if err != nil {
+		return nil, err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	s := &rotatingFileSink{
+		path:    file,
+		level:   level,
+		cfg:     cfg,
+		file:    f,
+		out:     bufio.NewWriterSize(f, bufferSize),
+		written: info.Size(),
+		stop:    make(chan struct{}),
+	}
+
+	if cfg.DailyRotate {
+		go s.dailyRotateLoop()
+	}
+
+	return s, nil
+}
+
+func (s *rotatingFileSink) Emit(_ Meta, data []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.cfg.MaxSize > 0 && s.written+int64(len(data)) > s.cfg.MaxSize {
+		if err := s.rotateLocked(); err != nil {
+			return err
+		}
+	}
+
+	n, err := s.out.Write(data)
+	s.written += int64(n)
+	return err
+}
+
+func (s *rotatingFileSink) Flush() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.out.Flush()
+}
+
+// Close stops the daily-rotation goroutine (if any) and closes the backing
+// file. It's safe to call more than once; only the first call does the work
+// and later calls return the same result.
+func (s *rotatingFileSink) Close() error {
+	s.closeOnce.Do(func() {
+		close(s.stop)
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if err := s.out.Flush(); err != nil {
+			s.file.Close()
+			s.closeErr = err
+			return
+		}
+		s.closeErr = s.file.Close()
+	})
+	return s.closeErr
+}
+
+// Rotate renames the current file to a timestamped backup, reopens a fresh
+// file at path, and asynchronously compresses and prunes old backups. It's
+// exported so a SIGHUP handler can trigger a manual rotation.
+func (s *rotatingFileSink) Rotate() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.rotateLocked()
+}
+
+func (s *rotatingFileSink) rotateLocked() error {
+	if err := s.out.Flush(); err != nil {
+		return err
+	}
+	if err := s.file.Close(); err != nil {
+		return err
+	}
+
+	backup := s.backupName(time.Now())
+	if err := os.Rename(s.path, backup); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	f, err := os.OpenFile(s.path, os.O_APPEND|os.O_WRONLY|os.O_CREATE, 0666)
+	if err != nil {
+		return err
+	}
+	s.file = f
+	s.out = bufio.NewWriterSize(f, bufferSize)
+	s.written = 0
+
+	go s.finishRotation(backup)
+	return nil
+}
+
+// backupName formats the rotated file name, mirroring glog's
+// program.host.user.log.LEVEL.YYYYMMDD-HHMMSS.pid scheme.
+func (s *rotatingFileSink) backupName(at time.Time) string {
+	dir := filepath.Dir(s.path)
+	return filepath.Join(dir, fmt.Sprintf("%s.%s.%s.log.%s.%s.%d",
+		program, host, userName, s.level.String(), at.Format("20060102-150405"), pid))
+}
+
+// finishRotation compresses the just-rotated backup (if configured) and
+// prunes old backups per MaxAge/MaxBackups. It runs off the sink's lock
+// since both steps are slow I/O that shouldn't block Emit.
+func (s *rotatingFileSink) finishRotation(backup string) {
+	if s.cfg.Compress {
+		if err := gzipFile(backup); err == nil {
+			backup += ".gz"
+		}
+	}
+	s.prune()
+}
+
+// gzipFile compresses src in place, writing src+".gz" and removing src on
+// success.
+func gzipFile(src string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.Create(src + ".gz")
+	if err != nil {
+		return err
+	}
+	gw := gzip.NewWriter(out)
+	if _, err := io.Copy(gw, in); err != nil {
+		gw.Close()
+		out.Close()
+		os.Remove(src + ".gz")
+		return err
+	}
+	if err := gw.Close(); err != nil {
+		out.Close()
+		os.Remove(src + ".gz")
+		return err
+	}
+	if err := out.Close(); err != nil {
+		os.Remove(src + ".gz")
+		return err
+	}
+	return os.Remove(src)
+}
+
+// prune deletes backups of this sink's file older than MaxAge, and all but
+// the MaxBackups most recent, whichever rules are configured (MaxAge == 0
+// and MaxBackups == 0 each disable their own check).
+func (s *rotatingFileSink) prune() {
+	if s.cfg.MaxAge <= 0 && s.cfg.MaxBackups <= 0 {
+		return
+	}
+
+	dir := filepath.Dir(s.path)
+	prefix := fmt.Sprintf("%s.%s.%s.log.%s.", program, host, userName, s.level.String())
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return
+	}
+
+	var backups []os.FileInfo
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasPrefix(e.Name(), prefix) {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			continue
+		}
+		backups = append(backups, info)
+	}
+	sort.Slice(backups, func(i, j int) bool {
+		return backups[i].ModTime().After(backups[j].ModTime())
+	})
+
+	now := time.Now()
+	for i, info := range backups {
+		remove := false
+		if s.cfg.MaxBackups > 0 && i >= s.cfg.MaxBackups {
+			remove = true
+		}
+		if s.cfg.MaxAge > 0 && now.Sub(info.ModTime()) > s.cfg.MaxAge {
+			remove = true
+		}
+		if remove {
+			os.Remove(filepath.Join(dir, info.Name()))
+		}
+	}
+}
+
+// dailyRotateLoop rotates the file once per day at cfg.RotateAt, until the
+// sink is closed.
+func (s *rotatingFileSink) dailyRotateLoop() {
+	for {
+		next := nextRotateAt(time.Now(), s.cfg.RotateAt)
+		t := time.NewTimer(time.Until(next))
+		select {
+		case <-t.C:
+			s.Rotate()
+		case <-s.stop:
+			t.Stop()
+			return
+		}
+	}
+}
+
+// nextRotateAt returns the next wall-clock time strictly after now that falls
+// on the given offset from midnight.
+func nextRotateAt(now time.Time, at time.Duration) time.Time {
+	midnight := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	next := midnight.Add(at)
+	if !next.After(now) {
+		next = next.Add(24 * time.Hour)
+	}
+	return next
+}
+
+// Rotate triggers a manual rotation on every sink that supports it (i.e.
+// every RotatingFileSink), for use from a SIGHUP handler.
+func (lg *Logger) Rotate() error {
+	var firstErr error
+	for _, s := range lg.lt.sinks {
+		if r, ok := s.(Rotator); ok {
+			if err := r.Rotate(); err != nil && firstErr == nil {
+				firstErr = err
+			}
+		}
+	}
+	return firstErr
+}
@@ -0,0 +1,126 @@
+package logit
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRotatingFileSinkCloseTwice(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.log")
+	s, err := RotatingFileSink(path, Linfo, RotateConfig{})
+	if err != nil {
+		t.Fatalf("RotatingFileSink: %v", err)
+	}
+
+	if err := s.Close(); err != nil {
+		t.Fatalf("first Close: %v", err)
+	}
+	if err := s.Close(); err != nil {
+		t.Fatalf("second Close: %v", err)
+	}
+}
+
+func TestRotatingFileSinkMaxSizeRotates(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "test.log")
+	sink, err := RotatingFileSink(path, Linfo, RotateConfig{MaxSize: 8})
+	if err != nil {
+		t.Fatalf("RotatingFileSink: %v", err)
+	}
+	defer sink.Close()
+
+	if err := sink.Emit(Meta{}, []byte("1234567\n")); err != nil {
+		t.Fatalf("Emit: %v", err)
+	}
+	if err := sink.Emit(Meta{}, []byte("triggers rotation\n")); err != nil {
+		t.Fatalf("Emit: %v", err)
+	}
+	if err := sink.Flush(); err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+
+	s := sink.(*rotatingFileSink)
+	if want := int64(len("triggers rotation\n")); s.written != want {
+		t.Errorf("written not reset after rotation, got %d, want %d", s.written, want)
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Errorf("fresh file missing at %s after rotation: %v", path, err)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("ReadDir: %v", err)
+	}
+	var backups int
+	for _, e := range entries {
+		if e.Name() != "test.log" {
+			backups++
+		}
+	}
+	if backups != 1 {
+		t.Errorf("got %d backup files, want 1: %v", backups, entries)
+	}
+}
+
+func TestRotatingFileSinkFinishRotationCompresses(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "test.log")
+	sink, err := RotatingFileSink(path, Linfo, RotateConfig{Compress: true})
+	if err != nil {
+		t.Fatalf("RotatingFileSink: %v", err)
+	}
+	defer sink.Close()
+
+	backup := filepath.Join(dir, "backup.log")
+	if err := os.WriteFile(backup, []byte("some log data\n"), 0666); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	s := sink.(*rotatingFileSink)
+	s.finishRotation(backup)
+
+	if _, err := os.Stat(backup); !os.IsNotExist(err) {
+		t.Errorf("uncompressed backup still present after finishRotation: %v", err)
+	}
+	if _, err := os.Stat(backup + ".gz"); err != nil {
+		t.Errorf("compressed backup missing: %v", err)
+	}
+}
+
+func TestRotatingFileSinkPruneMaxBackups(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "test.log")
+	sink, err := RotatingFileSink(path, Linfo, RotateConfig{MaxBackups: 1})
+	if err != nil {
+		t.Fatalf("RotatingFileSink: %v", err)
+	}
+	defer sink.Close()
+
+	s := sink.(*rotatingFileSink)
+	prefix := s.backupName(time.Now())
+	for i := 0; i < 3; i++ {
+		name := prefix + "." + strings.Repeat("x", i+1)
+		if err := os.WriteFile(name, []byte("data\n"), 0666); err != nil {
+			t.Fatalf("WriteFile: %v", err)
+		}
+	}
+
+	s.prune()
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("ReadDir: %v", err)
+	}
+	var remaining int
+	for _, e := range entries {
+		if e.Name() != "test.log" {
+			remaining++
+		}
+	}
+	if remaining != 1 {
+		t.Errorf("got %d backups remaining after prune with MaxBackups=1, want 1: %v", remaining, entries)
+	}
+}
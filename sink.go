@@ -0,0 +1,157 @@
+package logit
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"runtime"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Meta describes a single log record, independent of how its header was
+// rendered, so a Sink can either write the preformatted bytes as-is or
+// re-render the record in its own wire format.
+type Meta struct {
+	Level     Level
+	Time      time.Time
+	Pid       int
+	Goroutine int64
+	File      string
+	Line      int
+	Message   []byte // the formatted message body, header and trailing newline excluded
+}
+
+// Sink receives formatted log records. Emit is called with the header and
+// message already combined into data (so a Sink that just wants today's
+// behavior can write data verbatim), plus the Meta describing the same
+// record for sinks that want to re-render it. Flush is called by the owning
+// Logger's flush daemon (which New starts only when Lfile is set) and by its
+// Close method, which then calls Close.
+type Sink interface {
+	Emit(meta Meta, data []byte) error
+	Flush() error
+	Close() error
+}
+
+// goroutineID returns the id of the calling goroutine, parsed out of the
+// header line runtime.Stack prints ("goroutine 123 [running]:"). It's best
+// effort: a failure to parse yields 0 rather than an error, since goroutine
+// id is diagnostic metadata, not something callers should need to check.
+func goroutineID() int64 {
+	var buf [64]byte
+	n := runtime.Stack(buf[:], false)
+	b := buf[:n]
+	const prefix = "goroutine "
+	if len(b) < len(prefix) || string(b[:len(prefix)]) != prefix {
+		return 0
+	}
+	b = b[len(prefix):]
+	end := 0
+	for end < len(b) && b[end] >= '0' && b[end] <= '9' {
+		end++
+	}
+	id, err := strconv.ParseInt(string(b[:end]), 10, 64)
+	if err != nil {
+		return 0
+	}
+	return id
+}
+
+// fileSink writes records to a file through a buffered writer, matching the
+// Logger's previous hardcoded file output.
+type fileSink struct {
+	mu   sync.Mutex
+	file *os.File
+	out  *bufio.Writer
+}
+
+// FileSink opens (or creates) file in append mode and returns a Sink that
+// writes to it through a buffer of bufferSize bytes.
+func FileSink(file string) (Sink, error) {
+	f, err := os.OpenFile(file, os.O_APPEND|os.O_WRONLY|os.O_CREATE, 0666)
+	if err != nil {
+		return nil, err
+	}
+	return &fileSink{file: f, out: bufio.NewWriterSize(f, bufferSize)}, nil
+}
+
+func (s *fileSink) Emit(_ Meta, data []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, err := s.out.Write(data)
+	return err
+}
+
+func (s *fileSink) Flush() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.out.Flush()
+}
+
+func (s *fileSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.out.Flush(); err != nil {
+		s.file.Close()
+		return err
+	}
+	return s.file.Close()
+}
+
+// stderrSink writes records to os.Stderr. It has nothing to flush or close;
+// os.Stderr outlives the Logger.
+type stderrSink struct{}
+
+// StderrSink returns a Sink that writes records to os.Stderr.
+func StderrSink() Sink {
+	return stderrSink{}
+}
+
+func (stderrSink) Emit(_ Meta, data []byte) error {
+	_, err := os.Stderr.Write(data)
+	return err
+}
+
+func (stderrSink) Flush() error { return nil }
+func (stderrSink) Close() error { return nil }
+
+// writerSink adapts an arbitrary io.Writer into a Sink. If w also
+// implements io.Closer, Close closes it; otherwise Close is a no-op, since
+// closing the underlying writer is the caller's responsibility when it was
+// supplied from outside the package.
+type writerSink struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// WriterSink returns a Sink that writes records to w.
+func WriterSink(w io.Writer) Sink {
+	return &writerSink{w: w}
+}
+
+func (s *writerSink) Emit(_ Meta, data []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, err := s.w.Write(data)
+	return err
+}
+
+func (s *writerSink) Flush() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if f, ok := s.w.(interface{ Flush() error }); ok {
+		return f.Flush()
+	}
+	return nil
+}
+
+func (s *writerSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if c, ok := s.w.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
@@ -0,0 +1,72 @@
+package logit
+
+import (
+	"bytes"
+	"encoding/binary"
+	"net"
+	"strconv"
+	"strings"
+)
+
+// journaldSocket is the well-known path of journald's native datagram
+// socket.
+const journaldSocket = "/run/systemd/journal/socket"
+
+// journaldSink speaks journald's native protocol directly over its unix
+// datagram socket, rather than relying on a "<N>" syslog-style prefix in the
+// rendered header: each record is sent as a set of NAME=value fields,
+// including the structured fields journald indexes on (PRIORITY, CODE_FILE,
+// CODE_LINE, MESSAGE, SYSLOG_IDENTIFIER).
+type journaldSink struct {
+	conn       *net.UnixConn
+	identifier string
+}
+
+// JournaldSink dials the local journald socket and returns a Sink that
+// emits structured fields tagged with identifier. If identifier is empty,
+// the running program's name is used.
+func JournaldSink(identifier string) (Sink, error) {
+	if identifier == "" {
+		identifier = program
+	}
+	conn, err := net.DialUnix("unixgram", nil, &net.UnixAddr{Name: journaldSocket, Net: "unixgram"})
+	if err != nil {
+		return nil, err
+	}
+	return &journaldSink{conn: conn, identifier: identifier}, nil
+}
+
+func (s *journaldSink) Emit(meta Meta, data []byte) error {
+	var buf bytes.Buffer
+	writeJournaldField(&buf, "PRIORITY", strconv.Itoa(int(journalNum[meta.Level])))
+	writeJournaldField(&buf, "SYSLOG_IDENTIFIER", s.identifier)
+	writeJournaldField(&buf, "CODE_FILE", meta.File)
+	writeJournaldField(&buf, "CODE_LINE", strconv.Itoa(meta.Line))
+	writeJournaldField(&buf, "MESSAGE", string(bytes.TrimRight(meta.Message, "\n")))
+	_, err := s.conn.Write(buf.Bytes())
+	return err
+}
+
+// writeJournaldField appends a single field to buf in journald's native
+// wire format: "NAME=value\n" for values with no embedded newline, or
+// "NAME\n" followed by an 8-byte little-endian length and the raw value for
+// values that do (e.g. a multi-line stack trace in MESSAGE).
+func writeJournaldField(buf *bytes.Buffer, name, value string) {
+	if !strings.Contains(value, "\n") {
+		buf.WriteString(name)
+		buf.WriteByte('=')
+		buf.WriteString(value)
+		buf.WriteByte('\n')
+		return
+	}
+	buf.WriteString(name)
+	buf.WriteByte('\n')
+	var length [8]byte
+	binary.LittleEndian.PutUint64(length[:], uint64(len(value)))
+	buf.Write(length[:])
+	buf.WriteString(value)
+	buf.WriteByte('\n')
+}
+
+func (s *journaldSink) Flush() error { return nil }
+func (s *journaldSink) Close() error { return s.conn.Close() }
@@ -0,0 +1,44 @@
+package logit
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+)
+
+func TestWriteJournaldFieldSimple(t *testing.T) {
+	var buf bytes.Buffer
+	writeJournaldField(&buf, "PRIORITY", "3")
+	if got, want := buf.String(), "PRIORITY=3\n"; got != want {
+		t.Errorf("writeJournaldField = %q, want %q", got, want)
+	}
+}
+
+func TestWriteJournaldFieldMultiline(t *testing.T) {
+	var buf bytes.Buffer
+	value := "line one\nline two"
+	writeJournaldField(&buf, "MESSAGE", value)
+
+	data := buf.Bytes()
+	wantPrefix := []byte("MESSAGE\n")
+	if !bytes.HasPrefix(data, wantPrefix) {
+		t.Fatalf("framing missing NAME\\n prefix: %q", data)
+	}
+	data = data[len(wantPrefix):]
+
+	if len(data) < 8 {
+		t.Fatalf("framing missing 8-byte length, got %d bytes", len(data))
+	}
+	length := binary.LittleEndian.Uint64(data[:8])
+	if int(length) != len(value) {
+		t.Errorf("encoded length = %d, want %d", length, len(value))
+	}
+	data = data[8:]
+
+	if string(data[:len(value)]) != value {
+		t.Errorf("encoded value = %q, want %q", data[:len(value)], value)
+	}
+	if data[len(value)] != '\n' {
+		t.Errorf("missing trailing newline after value")
+	}
+}
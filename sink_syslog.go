@@ -0,0 +1,49 @@
+package logit
+
+import (
+	"log/syslog"
+	"strings"
+)
+
+// syslogSink forwards records to the local syslog daemon via the standard
+// library's syslog client, mapping Level onto the matching severity while
+// keeping the facility fixed at construction time.
+type syslogSink struct {
+	w *syslog.Writer
+}
+
+// SyslogSink dials the local syslog daemon and returns a Sink that logs at
+// facility, tagged with tag. Each record's severity is derived from its
+// Level rather than from facility, which carries only the facility bits.
+func SyslogSink(facility syslog.Priority, tag string) (Sink, error) {
+	w, err := syslog.New(facility, tag)
+	if err != nil {
+		return nil, err
+	}
+	return &syslogSink{w: w}, nil
+}
+
+func (s *syslogSink) Emit(meta Meta, data []byte) error {
+	msg := strings.TrimRight(string(data), "\n")
+	switch meta.Level {
+	case Lcrit, Lfatal:
+		return s.w.Crit(msg)
+	case Lalert:
+		return s.w.Alert(msg)
+	case Lerr:
+		return s.w.Err(msg)
+	case Lwarning:
+		return s.w.Warning(msg)
+	case Lnotice:
+		return s.w.Notice(msg)
+	case Linfo:
+		return s.w.Info(msg)
+	case Ldebug:
+		return s.w.Debug(msg)
+	default:
+		return s.w.Info(msg)
+	}
+}
+
+func (s *syslogSink) Flush() error { return nil }
+func (s *syslogSink) Close() error { return s.w.Close() }
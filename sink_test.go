@@ -0,0 +1,51 @@
+package logit
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestWriterSinkRoundTrip(t *testing.T) {
+	var buf bytes.Buffer
+	s := WriterSink(&buf)
+
+	if err := s.Emit(Meta{}, []byte("hello\n")); err != nil {
+		t.Fatalf("Emit: %v", err)
+	}
+	if err := s.Emit(Meta{}, []byte("world\n")); err != nil {
+		t.Fatalf("Emit: %v", err)
+	}
+	if err := s.Flush(); err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+	if got, want := buf.String(), "hello\nworld\n"; got != want {
+		t.Errorf("buf = %q, want %q", got, want)
+	}
+
+	if err := s.Close(); err != nil {
+		t.Errorf("Close: %v", err)
+	}
+}
+
+func TestWriterSinkCloseClosesCloser(t *testing.T) {
+	c := &closeTrackingWriter{}
+	s := WriterSink(c)
+	if err := s.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	if !c.closed {
+		t.Error("Close did not close the underlying io.Closer")
+	}
+}
+
+// closeTrackingWriter is an io.Writer + io.Closer double used to verify
+// writerSink.Close delegates to the underlying writer's Close when present.
+type closeTrackingWriter struct {
+	bytes.Buffer
+	closed bool
+}
+
+func (c *closeTrackingWriter) Close() error {
+	c.closed = true
+	return nil
+}
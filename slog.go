@@ -0,0 +1,110 @@
+package logit
+
+import (
+	"context"
+	"log/slog"
+	"runtime"
+	"strings"
+)
+
+// slogHandler implements slog.Handler by funneling every Record through lg,
+// mapping slog levels onto this package's Level and slog.Attrs onto the
+// structured key/value fields used by Infow and its peers.
+type slogHandler struct {
+	lg     *Logger
+	prefix string // group prefix prepended to attribute keys, set by WithGroup
+}
+
+// SlogHandler returns a slog.Handler that routes log/slog calls through
+// lg's sinks, header format, and level filter, so callers that prefer the
+// log/slog idioms don't lose any of that configuration.
+func (lg *Logger) SlogHandler() slog.Handler {
+	return &slogHandler{lg: lg}
+}
+
+// levelFromSlog maps a slog.Level onto a Level, rounding slog's
+// finer-grained levels (e.g. Info+2) down to the highest of Info/Warn/Error
+// at or below them; anything below Info maps to Debug.
+func levelFromSlog(level slog.Level) Level {
+	switch {
+	case level < slog.LevelInfo:
+		return Ldebug
+	case level < slog.LevelWarn:
+		return Linfo
+	case level < slog.LevelError:
+		return Lwarning
+	default:
+		return Lerr
+	}
+}
+
+// Enabled mirrors v's two-stage check: the cheap global-level test
+// short-circuits the common case, but when vmodule rules are configured it
+// can't yet know whether one applies to the real call site -- slog hasn't
+// captured that PC yet, and by the time Handle sees it Enabled has already
+// decided whether to build the Record at all. So it optimistically reports
+// true whenever any vmodule rule exists, leaving the authoritative decision
+// to Handle's VAt check against the record's actual PC.
+func (h *slogHandler) Enabled(_ context.Context, level slog.Level) bool {
+	lv := levelFromSlog(level)
+	if lv <= h.lg.level.get() {
+		return true
+	}
+	return h.lg.hasVModuleRules()
+}
+
+func (h *slogHandler) Handle(_ context.Context, r slog.Record) error {
+	level := levelFromSlog(r.Level)
+	file, line := callerFromPC(r.PC)
+	v := h.lg.VAt(level, file)
+	if !v.ok {
+		return nil
+	}
+
+	fields := make([]interface{}, 0, r.NumAttrs()*2)
+	r.Attrs(func(a slog.Attr) bool {
+		fields = append(fields, h.prefix+a.Key, a.Value.Any())
+		return true
+	})
+
+	v.lt.printwAt(level, baseName(file), line, r.Message, h.lg.allFields(fields)...)
+	return nil
+}
+
+// callerFromPC resolves the full file path and line number for a program
+// counter captured by a slog.Record, so records logged through SlogHandler
+// are attributed to the application's call site rather than a frame inside
+// log/slog itself. The full path is kept (rather than reduced to a short
+// name right away) so VAt can still match path-segment vmodule patterns
+// against it; callers that render the record reduce it via baseName.
+func callerFromPC(pc uintptr) (string, int) {
+	if pc == 0 {
+		return "???", 1
+	}
+	frame, _ := runtime.CallersFrames([]uintptr{pc}).Next()
+	if frame.File == "" {
+		return "???", 1
+	}
+	return frame.File, frame.Line
+}
+
+// baseName reduces a full file path to the short name used in headers and
+// by plain (non-path-segment) vmodule patterns.
+func baseName(file string) string {
+	if slash := strings.LastIndex(file, "/"); slash >= 0 {
+		return file[slash+1:]
+	}
+	return file
+}
+
+func (h *slogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	fields := make([]interface{}, 0, len(attrs)*2)
+	for _, a := range attrs {
+		fields = append(fields, h.prefix+a.Key, a.Value.Any())
+	}
+	return &slogHandler{lg: h.lg.With(fields...), prefix: h.prefix}
+}
+
+func (h *slogHandler) WithGroup(name string) slog.Handler {
+	return &slogHandler{lg: h.lg, prefix: h.prefix + name + "."}
+}
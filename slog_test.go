@@ -0,0 +1,49 @@
+package logit
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestSlogHandlerAttributesCallerSite(t *testing.T) {
+	var buf bytes.Buffer
+	lg, err := New("", 0, Linfo, 0, WriterSink(&buf))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	defer lg.Close()
+
+	logger := slog.New(lg.SlogHandler())
+	logger.Info("hello")
+
+	out := buf.String()
+	if strings.Contains(out, "slog/logger.go") || strings.Contains(out, "slog.go") {
+		t.Fatalf("output attributed to log/slog internals, not the caller: %q", out)
+	}
+	if !strings.Contains(out, "slog_test.go") {
+		t.Fatalf("output missing caller file slog_test.go: %q", out)
+	}
+}
+
+func TestSlogHandlerHonorsVModuleForCallerFile(t *testing.T) {
+	var buf bytes.Buffer
+	lg, err := New("", 0, Lwarning, 0, WriterSink(&buf))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	defer lg.Close()
+
+	if err := lg.SetVModule("slog_test.go=7"); err != nil {
+		t.Fatalf("SetVModule: %v", err)
+	}
+
+	logger := slog.New(lg.SlogHandler())
+	logger.Info("boosted by vmodule")
+
+	out := buf.String()
+	if !strings.Contains(out, "boosted by vmodule") {
+		t.Fatalf("vmodule rule for the real caller file didn't raise verbosity for SlogHandler; got %q", out)
+	}
+}
@@ -0,0 +1,36 @@
+package logit
+
+import (
+	"log"
+	"strings"
+)
+
+// stdLogWriter adapts a Logger+Level pair to an io.Writer suitable for
+// log.New, so a stdlib log.Logger's output is funneled through this
+// package's sinks and header format instead of its own.
+type stdLogWriter struct {
+	lg    *Logger
+	level Level
+}
+
+// Write logs p, a single line written by the wrapped log.Logger, at w's
+// configured level. The reported file:line necessarily point at the
+// stdlib log package's own call into Write rather than the application's
+// original Printf/Println call site, since that's the only frame visible
+// from here.
+func (w stdLogWriter) Write(p []byte) (int, error) {
+	v := w.lg.v(w.level)
+	if v.ok {
+		v.lt.printDepth(w.level, 0, strings.TrimSuffix(string(p), "\n"))
+	}
+	return len(p), nil
+}
+
+// StandardLogger returns a *log.Logger that funnels every line it writes
+// into lg at level, for handing to APIs that expect the stdlib logger --
+// most commonly http.Server.ErrorLog or a database/sql driver's logger
+// hook. Flags are set to 0 since formatHeader already supplies a
+// timestamp, severity, and call site of its own.
+func (lg *Logger) StandardLogger(level Level) *log.Logger {
+	return log.New(stdLogWriter{lg: lg, level: level}, "", 0)
+}
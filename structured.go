@@ -0,0 +1,280 @@
+package logit
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// render assembles the final buffer for a record, dispatching on the
+// Logger's configured encoding: JSON (Ljson), logfmt (Llogfmt), or the
+// classic human-readable header (the default when neither flag is set).
+// keysAndValues may be nil; all three encoders accept that and just emit
+// msg with no additional fields.
+func (lt *loggerT) render(lev Level, file string, line int, msg string, keysAndValues []interface{}) *buffer {
+	switch {
+	case lt.flag&Ljson == Ljson:
+		return lt.renderJSON(lev, file, line, msg, keysAndValues)
+	case lt.flag&Llogfmt == Llogfmt:
+		return lt.renderLogfmt(lev, file, line, msg, keysAndValues)
+	default:
+		return lt.renderText(lev, file, line, msg, keysAndValues)
+	}
+}
+
+// renderText reproduces the original human-readable header exactly, with
+// any key/value pairs appended as trailing logfmt-style fields.
+func (lt *loggerT) renderText(lev Level, file string, line int, msg string, keysAndValues []interface{}) *buffer {
+	buf := lt.formatHeader(lev, file, line)
+	buf.WriteString(msg)
+	writeLogfmtFields(buf, keysAndValues)
+	if buf.Len() == 0 || buf.Bytes()[buf.Len()-1] != '\n' {
+		buf.WriteByte('\n')
+	}
+	return buf
+}
+
+// renderLogfmt renders ts/level/file/msg plus keysAndValues as k=v pairs.
+func (lt *loggerT) renderLogfmt(lev Level, file string, line int, msg string, keysAndValues []interface{}) *buffer {
+	buf := lt.getBuffer()
+	buf.WriteString("ts=")
+	buf.WriteString(time.Now().Format(time.RFC3339Nano))
+	buf.WriteString(" level=")
+	buf.WriteString(lev.String())
+	buf.WriteString(" file=")
+	buf.WriteString(file)
+	buf.WriteByte(':')
+	buf.WriteString(strconv.Itoa(line))
+	buf.WriteString(" msg=")
+	writeLogfmtValue(buf, msg)
+	writeLogfmtFields(buf, keysAndValues)
+	buf.WriteByte('\n')
+	return buf
+}
+
+// writeLogfmtFields appends each keysAndValues pair to buf as " key=value".
+// An odd trailing key (no matching value) is rendered with a "!MISSING"
+// value, mirroring how sugared structured loggers elsewhere handle it.
+func writeLogfmtFields(buf *buffer, keysAndValues []interface{}) {
+	for i := 0; i+1 < len(keysAndValues); i += 2 {
+		buf.WriteByte(' ')
+		buf.WriteString(toString(keysAndValues[i]))
+		buf.WriteByte('=')
+		writeLogfmtValue(buf, toString(keysAndValues[i+1]))
+	}
+	if len(keysAndValues)%2 == 1 {
+		buf.WriteByte(' ')
+		buf.WriteString(toString(keysAndValues[len(keysAndValues)-1]))
+		buf.WriteString("=!MISSING")
+	}
+}
+
+// writeLogfmtValue writes s as a logfmt value, quoting it if it contains a
+// space, '=', or '"'.
+func writeLogfmtValue(buf *buffer, s string) {
+	if strings.ContainsAny(s, " =\"") {
+		buf.WriteString(strconv.Quote(s))
+		return
+	}
+	buf.WriteString(s)
+}
+
+// renderJSON renders the record as a single-line JSON object.
+func (lt *loggerT) renderJSON(lev Level, file string, line int, msg string, keysAndValues []interface{}) *buffer {
+	buf := lt.getBuffer()
+	buf.WriteString(`{"ts":"`)
+	buf.WriteString(time.Now().Format(time.RFC3339Nano))
+	buf.WriteString(`","level":"`)
+	buf.WriteString(lev.String())
+	buf.WriteString(`","file":"`)
+	buf.WriteString(file)
+	buf.WriteByte(':')
+	buf.WriteString(strconv.Itoa(line))
+	buf.WriteString(`","msg":`)
+	buf.WriteString(strconv.Quote(msg))
+	for i := 0; i+1 < len(keysAndValues); i += 2 {
+		buf.WriteString(`,"`)
+		buf.WriteString(toString(keysAndValues[i]))
+		buf.WriteString(`":`)
+		writeJSONValue(buf, keysAndValues[i+1])
+	}
+	if len(keysAndValues)%2 == 1 {
+		buf.WriteString(`,"`)
+		buf.WriteString(toString(keysAndValues[len(keysAndValues)-1]))
+		buf.WriteString(`":"!MISSING"`)
+	}
+	buf.WriteString("}\n")
+	return buf
+}
+
+// writeJSONValue writes v as a JSON value, encoding strings, errors, and
+// Stringers as quoted strings, numeric and bool kinds as JSON literals, and
+// falling back to a quoted %v for everything else.
+func writeJSONValue(buf *buffer, v interface{}) {
+	switch t := v.(type) {
+	case string:
+		buf.WriteString(strconv.Quote(t))
+	case error:
+		buf.WriteString(strconv.Quote(t.Error()))
+	case bool:
+		buf.WriteString(strconv.FormatBool(t))
+	case int:
+		buf.WriteString(strconv.Itoa(t))
+	case int8:
+		buf.WriteString(strconv.FormatInt(int64(t), 10))
+	case int16:
+		buf.WriteString(strconv.FormatInt(int64(t), 10))
+	case int32:
+		buf.WriteString(strconv.FormatInt(int64(t), 10))
+	case int64:
+		buf.WriteString(strconv.FormatInt(t, 10))
+	case uint:
+		buf.WriteString(strconv.FormatUint(uint64(t), 10))
+	case uint8:
+		buf.WriteString(strconv.FormatUint(uint64(t), 10))
+	case uint16:
+		buf.WriteString(strconv.FormatUint(uint64(t), 10))
+	case uint32:
+		buf.WriteString(strconv.FormatUint(uint64(t), 10))
+	case uint64:
+		buf.WriteString(strconv.FormatUint(t, 10))
+	case float32:
+		buf.WriteString(strconv.FormatFloat(float64(t), 'g', -1, 32))
+	case float64:
+		buf.WriteString(strconv.FormatFloat(t, 'g', -1, 64))
+	default:
+		buf.WriteString(strconv.Quote(toString(v)))
+	}
+}
+
+// toString renders v as a string for use as a field key or a non-JSON
+// field value, using its Error()/String() method when available.
+func toString(v interface{}) string {
+	switch t := v.(type) {
+	case string:
+		return t
+	case error:
+		return t.Error()
+	case fmt.Stringer:
+		return t.String()
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// With returns a child Logger that prepends keysAndValues to the fields of
+// every subsequent *w call, in addition to any fields already bound on lg.
+// The child shares lg's loggerT (and hence its Sinks) and starts from lg's
+// current level and vmodule configuration, but those may be changed
+// independently afterwards.
+func (lg *Logger) With(keysAndValues ...interface{}) *Logger {
+	child := &Logger{
+		lt:          lg.lt,
+		infoPrefix:  lg.infoPrefix,
+		debugPrefix: lg.debugPrefix,
+	}
+	child.level.set(lg.level.get())
+	if st, ok := lg.vmodule.Load().(*vmoduleState); ok {
+		child.vmodule.Store(st)
+	}
+	child.fields = append(append([]interface{}{}, lg.fields...), keysAndValues...)
+	return child
+}
+
+// allFields prepends lg's bound fields to keysAndValues for a single *w call.
+func (lg *Logger) allFields(keysAndValues []interface{}) []interface{} {
+	if len(lg.fields) == 0 {
+		return keysAndValues
+	}
+	return append(append([]interface{}{}, lg.fields...), keysAndValues...)
+}
+
+// Critw logs a structured message if verbosity is set appropriately.
+func (lg *Logger) Critw(msg string, keysAndValues ...interface{}) {
+	lg.v(Lcrit).Critw(msg, lg.allFields(keysAndValues)...)
+}
+
+// Alertw logs a structured message if verbosity is set appropriately.
+func (lg *Logger) Alertw(msg string, keysAndValues ...interface{}) {
+	lg.v(Lalert).Alertw(msg, lg.allFields(keysAndValues)...)
+}
+
+// Errorw logs a structured message if verbosity is set appropriately.
+func (lg *Logger) Errorw(msg string, keysAndValues ...interface{}) {
+	lg.v(Lerr).Errorw(msg, lg.allFields(keysAndValues)...)
+}
+
+// Warnw logs a structured message if verbosity is set appropriately.
+func (lg *Logger) Warnw(msg string, keysAndValues ...interface{}) {
+	lg.v(Lwarning).Warnw(msg, lg.allFields(keysAndValues)...)
+}
+
+// Noticew logs a structured message if verbosity is set appropriately.
+func (lg *Logger) Noticew(msg string, keysAndValues ...interface{}) {
+	lg.v(Lnotice).Noticew(msg, lg.allFields(keysAndValues)...)
+}
+
+// Infow logs a structured message if verbosity is set appropriately.
+func (lg *Logger) Infow(msg string, keysAndValues ...interface{}) {
+	lg.v(Linfo).Infow(msg, lg.allFields(keysAndValues)...)
+}
+
+// Debugw logs a structured message if verbosity is set appropriately.
+func (lg *Logger) Debugw(msg string, keysAndValues ...interface{}) {
+	lg.v(Ldebug).Debugw(msg, lg.allFields(keysAndValues)...)
+}
+
+// Fatalw logs a structured message and terminates the application.
+func (lg *Logger) Fatalw(msg string, keysAndValues ...interface{}) {
+	lg.lt.printw(Lfatal, 0, msg, lg.allFields(keysAndValues)...)
+}
+
+// Critw logs a structured message if verbosity is set appropriately.
+func (v verbose) Critw(msg string, keysAndValues ...interface{}) {
+	if v.ok {
+		v.lt.printw(Lcrit, 1, msg, keysAndValues...)
+	}
+}
+
+// Alertw logs a structured message if verbosity is set appropriately.
+func (v verbose) Alertw(msg string, keysAndValues ...interface{}) {
+	if v.ok {
+		v.lt.printw(Lalert, 1, msg, keysAndValues...)
+	}
+}
+
+// Errorw logs a structured message if verbosity is set appropriately.
+func (v verbose) Errorw(msg string, keysAndValues ...interface{}) {
+	if v.ok {
+		v.lt.printw(Lerr, 1, msg, keysAndValues...)
+	}
+}
+
+// Warnw logs a structured message if verbosity is set appropriately.
+func (v verbose) Warnw(msg string, keysAndValues ...interface{}) {
+	if v.ok {
+		v.lt.printw(Lwarning, 1, msg, keysAndValues...)
+	}
+}
+
+// Noticew logs a structured message if verbosity is set appropriately.
+func (v verbose) Noticew(msg string, keysAndValues ...interface{}) {
+	if v.ok {
+		v.lt.printw(Lnotice, 1, msg, keysAndValues...)
+	}
+}
+
+// Infow logs a structured message if verbosity is set appropriately.
+func (v verbose) Infow(msg string, keysAndValues ...interface{}) {
+	if v.ok {
+		v.lt.printw(Linfo, 1, msg, keysAndValues...)
+	}
+}
+
+// Debugw logs a structured message if verbosity is set appropriately.
+func (v verbose) Debugw(msg string, keysAndValues ...interface{}) {
+	if v.ok {
+		v.lt.printw(Ldebug, 1, msg, keysAndValues...)
+	}
+}
@@ -0,0 +1,82 @@
+package logit
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestWriteLogfmtValueQuoting(t *testing.T) {
+	cases := []struct {
+		in, want string
+	}{
+		{"plain", "plain"},
+		{"has space", `"has space"`},
+		{`has"quote`, `"has\"quote"`},
+		{"has=equals", `"has=equals"`},
+	}
+	for _, c := range cases {
+		buf := &buffer{}
+		writeLogfmtValue(buf, c.in)
+		if got := buf.String(); got != c.want {
+			t.Errorf("writeLogfmtValue(%q) = %q, want %q", c.in, got, c.want)
+		}
+	}
+}
+
+func TestWriteLogfmtFieldsOddKeyMissing(t *testing.T) {
+	buf := &buffer{}
+	writeLogfmtFields(buf, []interface{}{"k1", "v1", "dangling"})
+	if got, want := buf.String(), ` k1=v1 dangling=!MISSING`; got != want {
+		t.Errorf("writeLogfmtFields = %q, want %q", got, want)
+	}
+}
+
+func TestRenderLogfmtAndJSON(t *testing.T) {
+	var buf bytes.Buffer
+	lg, err := New("", 0, Linfo, Llogfmt, WriterSink(&buf))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	defer lg.Close()
+
+	lg.Infow("hello", "key", "val")
+	out := buf.String()
+	if !strings.Contains(out, "msg=hello") || !strings.Contains(out, "key=val") {
+		t.Errorf("logfmt output missing expected fields: %q", out)
+	}
+
+	buf.Reset()
+	lgJSON, err := New("", 0, Linfo, Ljson, WriterSink(&buf))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	defer lgJSON.Close()
+
+	lgJSON.Infow("hello", "key", "val")
+	out = buf.String()
+	if !strings.Contains(out, `"msg":"hello"`) || !strings.Contains(out, `"key":"val"`) {
+		t.Errorf("json output missing expected fields: %q", out)
+	}
+}
+
+func TestWithComposesFieldOrder(t *testing.T) {
+	var buf bytes.Buffer
+	lg, err := New("", 0, Linfo, Llogfmt, WriterSink(&buf))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	defer lg.Close()
+
+	child := lg.With("a", "1").With("b", "2")
+	child.Infow("msg", "c", "3")
+
+	out := buf.String()
+	ia, ib, ic := strings.Index(out, "a=1"), strings.Index(out, "b=2"), strings.Index(out, "c=3")
+	if ia < 0 || ib < 0 || ic < 0 {
+		t.Fatalf("missing bound/call fields in output: %q", out)
+	}
+	if !(ia < ib && ib < ic) {
+		t.Errorf("fields out of order, want a, b, c in bind order then call order: %q", out)
+	}
+}
@@ -0,0 +1,172 @@
+package logit
+
+import (
+	"fmt"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// vmoduleRule is a single pattern=level entry parsed from a SetVModule spec.
+// pattern may be a plain file glob (e.g. "gopher*.go") or a path-segment glob
+// containing a slash (e.g. "auth/*.go"), in which case it is matched against
+// the trailing path components of the caller's file.
+type vmoduleRule struct {
+	pattern string
+	level   Level
+}
+
+// vlevel is the cached result of resolving a file against the vmodule rule
+// list: the effective level, and whether any rule matched at all.
+type vlevel struct {
+	level Level
+	ok    bool
+}
+
+// vmoduleState holds the parsed rule set plus a cache of resolved levels
+// keyed by file name, so repeated v() calls for the same call site don't
+// re-walk the rule list.
+type vmoduleState struct {
+	rules []vmoduleRule
+	cache sync.Map // map[string]vlevel
+}
+
+// SetVModule sets the vmodule filter from a comma-separated list of
+// pattern=level entries, e.g. "gopher*=3,auth/*.go=4,main.go=2". Each pattern
+// is matched against the caller's source file path using
+// filepath.Match, with an extension so patterns containing a slash match
+// against the file's trailing path segments rather than requiring an exact
+// directory match. A file matching more than one pattern uses the level of
+// the last matching entry in the spec. Calling SetVModule invalidates any
+// per-file levels cached under the previous spec.
+func (lg *Logger) SetVModule(spec string) error {
+	var rules []vmoduleRule
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		eq := strings.LastIndex(entry, "=")
+		if eq < 0 {
+			return fmt.Errorf("logit: invalid vmodule entry %q: missing '='", entry)
+		}
+		pattern, levelStr := entry[:eq], entry[eq+1:]
+		if pattern == "" {
+			return fmt.Errorf("logit: invalid vmodule entry %q: empty pattern", entry)
+		}
+		n, err := strconv.Atoi(levelStr)
+		if err != nil {
+			return fmt.Errorf("logit: invalid vmodule entry %q: %v", entry, err)
+		}
+		rules = append(rules, vmoduleRule{pattern: pattern, level: Level(n)})
+	}
+
+	lg.vmodule.Store(&vmoduleState{rules: rules})
+	return nil
+}
+
+// vmoduleMatch reports whether file matches pattern, treating patterns that
+// contain a path separator as matching against the file's trailing segments
+// rather than the whole path.
+func vmoduleMatch(pattern, file string) bool {
+	if strings.Contains(pattern, "/") {
+		segs := strings.Count(pattern, "/") + 1
+		parts := strings.Split(file, "/")
+		if len(parts) > segs {
+			file = strings.Join(parts[len(parts)-segs:], "/")
+		}
+	} else if slash := strings.LastIndex(file, "/"); slash >= 0 {
+		file = file[slash+1:]
+	}
+	matched, err := filepath.Match(pattern, file)
+	return err == nil && matched
+}
+
+// hasVModuleRules reports whether any vmodule rule is currently configured,
+// for callers that need to know before a call site's file is available
+// (e.g. slogHandler.Enabled, which runs before slog has captured a PC).
+func (lg *Logger) hasVModuleRules() bool {
+	st, _ := lg.vmodule.Load().(*vmoduleState)
+	return st != nil && len(st.rules) > 0
+}
+
+// vmoduleLevel returns the effective vmodule level for file, or ok == false
+// if no configured rule applies to it. Results are cached per file until the
+// next SetVModule call.
+func (lg *Logger) vmoduleLevel(file string) vlevel {
+	st, _ := lg.vmodule.Load().(*vmoduleState)
+	if st == nil || len(st.rules) == 0 {
+		return vlevel{}
+	}
+
+	if cached, ok := st.cache.Load(file); ok {
+		return cached.(vlevel)
+	}
+
+	var vl vlevel
+	for _, r := range st.rules {
+		if vmoduleMatch(r.pattern, file) {
+			vl = vlevel{level: r.level, ok: true}
+		}
+	}
+	st.cache.Store(file, vl)
+	return vl
+}
+
+// callerFile returns the full file path (not the short name used by
+// caller()) for the stack frame `skip` levels above callerFile's own
+// caller, or ok == false if it cannot be determined. The full path is kept
+// so vmoduleMatch can match path-segment patterns like "auth/*.go" against
+// the caller's trailing directory components; vmoduleMatch itself reduces
+// it to a bare basename for plain patterns.
+func callerFile(skip int) (string, bool) {
+	_, file, _, ok := runtime.Caller(skip + 1)
+	if !ok {
+		return "", false
+	}
+	return file, true
+}
+
+// V is the exported form of v, for callers that want the verbose gate
+// itself (e.g. to chain Every/EveryT) rather than one of the
+// Logger.X convenience methods: lg.V(Linfo).Every(100).Infof(...).
+func (lg *Logger) V(level Level) verbose {
+	return lg.v(level)
+}
+
+// VDepth behaves like v but resolves vmodule rules against the file `depth`
+// frames above its caller's caller (depth 0 is the caller's caller), so
+// helpers that wrap the logging API still match the right call site.
+func (lg *Logger) VDepth(depth int, level Level) verbose {
+	if level <= lg.level.get() {
+		return verbose{ok: true, lt: lg.lt}
+	}
+	if !lg.hasVModuleRules() {
+		return verbose{lt: lg.lt}
+	}
+
+	file, ok := callerFile(2 + depth)
+	if !ok {
+		return verbose{lt: lg.lt}
+	}
+	return lg.vAt(level, file)
+}
+
+// VAt behaves like v but resolves vmodule rules against an already-known
+// file rather than walking the stack, for callers that recover their own
+// call site some other way (e.g. SlogHandler, from a slog.Record's PC).
+func (lg *Logger) VAt(level Level, file string) verbose {
+	if level <= lg.level.get() {
+		return verbose{ok: true, lt: lg.lt}
+	}
+	return lg.vAt(level, file)
+}
+
+// vAt is the shared vmodule-against-a-known-file check behind VDepth and
+// VAt, used once the global-level short-circuit has already been tried.
+func (lg *Logger) vAt(level Level, file string) verbose {
+	vl := lg.vmoduleLevel(file)
+	return verbose{ok: vl.ok && level <= vl.level, lt: lg.lt}
+}
@@ -0,0 +1,32 @@
+package logit
+
+import "testing"
+
+func TestVmoduleMatchPathSegment(t *testing.T) {
+	cases := []struct {
+		pattern string
+		file    string
+		want    bool
+	}{
+		{"auth/*.go", "internal/auth/login.go", true},
+		{"auth/*.go", "internal/other/login.go", false},
+		{"gopher*", "internal/auth/gopher_login.go", true},
+		{"main.go", "cmd/server/main.go", true},
+	}
+	for _, c := range cases {
+		if got := vmoduleMatch(c.pattern, c.file); got != c.want {
+			t.Errorf("vmoduleMatch(%q, %q) = %v, want %v", c.pattern, c.file, got, c.want)
+		}
+	}
+}
+
+func TestSetVModulePathSegmentRule(t *testing.T) {
+	lg := &Logger{}
+	if err := lg.SetVModule("auth/*.go=7"); err != nil {
+		t.Fatalf("SetVModule: %v", err)
+	}
+	vl := lg.vmoduleLevel("internal/auth/login.go")
+	if !vl.ok || vl.level != 7 {
+		t.Errorf("vmoduleLevel(internal/auth/login.go) = %+v, want ok=true level=7", vl)
+	}
+}